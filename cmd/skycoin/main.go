@@ -0,0 +1,59 @@
+// Command skycoin runs a skycoin node. This file only wires up the
+// -rpclisten flag added for src/api/walletrpc; the rest of this binary's
+// bootstrapping (visor, the HTTP API, P2P networking) lives outside this
+// snapshot of the module and is not reproduced here.
+package main
+
+import (
+	"flag"
+	"log"
+
+	"google.golang.org/grpc/credentials"
+
+	"github.com/skycoin/skycoin/src/api/walletrpc"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+func main() {
+	walletDir := flag.String("wallet-dir", "./wallets", "directory to store wallet files")
+	disableWalletAPI := flag.Bool("disable-wallet-api", false, "disable the wallet API, both HTTP and RPC")
+	rpcListen := flag.String("rpclisten", "", "address to serve the wallet gRPC API on, e.g. :7280; empty disables it")
+	rpcCert := flag.String("rpc-cert", "", "TLS certificate file for -rpclisten; empty serves plaintext")
+	rpcKey := flag.String("rpc-key", "", "TLS key file for -rpclisten; empty serves plaintext")
+	rpcClientCA := flag.String("rpc-client-ca", "", "PEM file of CAs trusted to authenticate client certificates on -rpclisten")
+	flag.Parse()
+
+	wallets, err := wallet.NewService(*walletDir, *disableWalletAPI)
+	if err != nil {
+		log.Fatalf("failed to load wallets: %v", err)
+	}
+
+	if *rpcListen == "" {
+		return
+	}
+
+	var creds credentials.TransportCredentials
+	if *rpcCert != "" || *rpcKey != "" {
+		var err error
+		creds, err = walletrpc.ServerTransportCredentials(*rpcCert, *rpcKey, *rpcClientCA)
+		if err != nil {
+			log.Fatalf("failed to set up -rpclisten TLS: %v", err)
+		}
+	}
+
+	// Balances, Unspents and Validator come from the visor subsystem in the
+	// full node, which this snapshot doesn't include, so RPCs that need
+	// them (SignTransaction, ScanAheadWalletAddresses,
+	// TransactionNotifications) answer Unavailable from this entrypoint
+	// instead of serving real requests; CreateWallet, EncryptWallet,
+	// UnlockWallet, NewAddresses, GetAddresses and GetWallets only need
+	// Wallets and already work.
+	srv := &walletrpc.Server{
+		Wallets: wallets,
+	}
+
+	log.Printf("wallet gRPC API listening on %s", *rpcListen)
+	if err := walletrpc.ListenAndServe(*rpcListen, srv, creds); err != nil {
+		log.Fatalf("-rpclisten server stopped: %v", err)
+	}
+}
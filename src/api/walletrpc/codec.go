@@ -0,0 +1,30 @@
+package walletrpc
+
+import (
+	"encoding/json"
+)
+
+// jsonCodec marshals gRPC messages as JSON instead of protobuf wire format.
+// This stands in for a real protoc-gen-go codegen step, which this build's
+// toolchain doesn't have; the message types here are plain structs, not
+// proto.Message, so the built-in proto codec cannot marshal them at all.
+//
+// It's installed on our *grpc.Server via grpc.CustomCodec in listen.go,
+// not via encoding.RegisterCodec, so it only ever applies to this package's
+// own server. encoding.RegisterCodec is a process-wide registry keyed by
+// codec name, and grpc-go's default codec is named "proto"; registering
+// this JSON codec under that name there would silently hijack the wire
+// format for every other gRPC service sharing the binary.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) String() string {
+	return "walletrpc-json"
+}
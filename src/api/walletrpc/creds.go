@@ -0,0 +1,55 @@
+package walletrpc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+
+	"google.golang.org/grpc/credentials"
+)
+
+// ServerTransportCredentials loads a server certificate/key pair from
+// certFile/keyFile and, if clientCAFile is non-empty, requires and verifies
+// a client certificate signed by it for every connection.
+func ServerTransportCredentials(certFile, keyFile, clientCAFile string) (credentials.TransportCredentials, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load server keypair: %v", err)
+	}
+
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+	}
+
+	if clientCAFile != "" {
+		caCert, err := ioutil.ReadFile(clientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA file: %v", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("failed to parse client CA file %s", clientCAFile)
+		}
+
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+		cfg.ClientCAs = pool
+	}
+
+	return credentials.NewTLS(cfg), nil
+}
+
+// ClientTransportCredentials loads the client certificate/key pair used to
+// authenticate to a Server configured with a clientCAFile
+func ClientTransportCredentials(certFile, keyFile, serverName string) (credentials.TransportCredentials, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load client keypair: %v", err)
+	}
+
+	return credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ServerName:   serverName,
+	}), nil
+}
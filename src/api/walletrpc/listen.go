@@ -0,0 +1,28 @@
+package walletrpc
+
+import (
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// ListenAndServe starts a gRPC server serving srv on addr. creds may be nil
+// to serve plaintext, which should only be used for local development.
+// cmd/skycoin's -rpclisten flag calls this.
+func ListenAndServe(addr string, srv WalletServiceServer, creds credentials.TransportCredentials) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	opts := []grpc.ServerOption{grpc.CustomCodec(jsonCodec{})}
+	if creds != nil {
+		opts = append(opts, grpc.Creds(creds))
+	}
+
+	s := grpc.NewServer(opts...)
+	RegisterWalletServiceServer(s, srv)
+
+	return s.Serve(lis)
+}
@@ -0,0 +1,292 @@
+// The message and service types below mirror walletrpc.proto by hand. There
+// is no protoc-gen-go in this build's toolchain to generate real
+// proto.Message implementations from it, so these are plain structs instead
+// of generated ones; codec.go defines a JSON codec that listen.go installs
+// on the server in place of grpc-go's default proto codec so that the
+// server can actually encode/decode them on the wire. If protoc-gen-go becomes available, this file should be deleted
+// and regenerated from walletrpc.proto instead of maintained by hand.
+
+package walletrpc
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// CreateWalletRequest is the request message for WalletService.CreateWallet
+type CreateWalletRequest struct {
+	Label string
+	Seed  string
+	Type  string
+	Xpub  string
+}
+
+// Wallet is the wire representation of a wallet.Wallet
+type Wallet struct {
+	Id        string
+	Label     string
+	Type      string
+	Addresses []string
+}
+
+// EncryptWalletRequest is the request message for WalletService.EncryptWallet
+type EncryptWalletRequest struct {
+	WalletId string
+	Password string
+}
+
+// UnlockWalletRequest is the request message for WalletService.UnlockWallet
+type UnlockWalletRequest struct {
+	WalletId       string
+	Password       string
+	TimeoutSeconds int64
+}
+
+// UnlockWalletResponse is the response message for WalletService.UnlockWallet
+type UnlockWalletResponse struct {
+}
+
+// NewAddressesRequest is the request message for WalletService.NewAddresses
+type NewAddressesRequest struct {
+	WalletId string
+	Num      uint64
+}
+
+// NewAddressesResponse is the response message for WalletService.NewAddresses
+type NewAddressesResponse struct {
+	Addresses []string
+}
+
+// GetAddressesRequest is the request message for WalletService.GetAddresses
+type GetAddressesRequest struct {
+	WalletId string
+}
+
+// GetAddressesResponse is the response message for WalletService.GetAddresses
+type GetAddressesResponse struct {
+	Addresses []string
+}
+
+// GetWalletsRequest is the request message for WalletService.GetWallets
+type GetWalletsRequest struct {
+}
+
+// GetWalletsResponse is the response message for WalletService.GetWallets
+type GetWalletsResponse struct {
+	Wallets []*Wallet
+}
+
+// SignTransactionRequest is the request message for WalletService.SignTransaction
+type SignTransactionRequest struct {
+	WalletId    string
+	HeadTime    uint64
+	Coins       uint64
+	DestAddress string
+}
+
+// SignTransactionResponse is the response message for WalletService.SignTransaction
+type SignTransactionResponse struct {
+	Transaction []byte
+}
+
+// ScanAheadWalletAddressesRequest is the request message for WalletService.ScanAheadWalletAddresses
+type ScanAheadWalletAddressesRequest struct {
+	WalletId string
+	ScanN    uint64
+}
+
+// TransactionNotificationsRequest is the request message for WalletService.TransactionNotifications
+type TransactionNotificationsRequest struct {
+	WalletId string
+}
+
+// TransactionNotification is streamed by WalletService.TransactionNotifications
+type TransactionNotification struct {
+	Txid   string
+	Height uint64
+}
+
+// WalletServiceServer is the server API for WalletService
+type WalletServiceServer interface {
+	CreateWallet(context.Context, *CreateWalletRequest) (*Wallet, error)
+	EncryptWallet(context.Context, *EncryptWalletRequest) (*Wallet, error)
+	UnlockWallet(context.Context, *UnlockWalletRequest) (*UnlockWalletResponse, error)
+	NewAddresses(context.Context, *NewAddressesRequest) (*NewAddressesResponse, error)
+	GetAddresses(context.Context, *GetAddressesRequest) (*GetAddressesResponse, error)
+	GetWallets(context.Context, *GetWalletsRequest) (*GetWalletsResponse, error)
+	SignTransaction(context.Context, *SignTransactionRequest) (*SignTransactionResponse, error)
+	ScanAheadWalletAddresses(context.Context, *ScanAheadWalletAddressesRequest) (*Wallet, error)
+	TransactionNotifications(*TransactionNotificationsRequest, WalletService_TransactionNotificationsServer) error
+}
+
+// WalletService_TransactionNotificationsServer is the server-streaming
+// handle for WalletService.TransactionNotifications
+type WalletService_TransactionNotificationsServer interface {
+	Send(*TransactionNotification) error
+	grpc.ServerStream
+}
+
+// RegisterWalletServiceServer registers srv with s, the same way
+// protoc-gen-go wires up every RPC service
+func RegisterWalletServiceServer(s *grpc.Server, srv WalletServiceServer) {
+	s.RegisterService(&_WalletService_serviceDesc, srv)
+}
+
+func _WalletService_CreateWallet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateWalletRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletServiceServer).CreateWallet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/walletrpc.WalletService/CreateWallet"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServiceServer).CreateWallet(ctx, req.(*CreateWalletRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletService_EncryptWallet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EncryptWalletRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletServiceServer).EncryptWallet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/walletrpc.WalletService/EncryptWallet"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServiceServer).EncryptWallet(ctx, req.(*EncryptWalletRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletService_UnlockWallet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnlockWalletRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletServiceServer).UnlockWallet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/walletrpc.WalletService/UnlockWallet"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServiceServer).UnlockWallet(ctx, req.(*UnlockWalletRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletService_NewAddresses_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NewAddressesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletServiceServer).NewAddresses(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/walletrpc.WalletService/NewAddresses"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServiceServer).NewAddresses(ctx, req.(*NewAddressesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletService_GetAddresses_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAddressesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletServiceServer).GetAddresses(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/walletrpc.WalletService/GetAddresses"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServiceServer).GetAddresses(ctx, req.(*GetAddressesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletService_GetWallets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetWalletsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletServiceServer).GetWallets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/walletrpc.WalletService/GetWallets"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServiceServer).GetWallets(ctx, req.(*GetWalletsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletService_SignTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SignTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletServiceServer).SignTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/walletrpc.WalletService/SignTransaction"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServiceServer).SignTransaction(ctx, req.(*SignTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletService_ScanAheadWalletAddresses_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScanAheadWalletAddressesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletServiceServer).ScanAheadWalletAddresses(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/walletrpc.WalletService/ScanAheadWalletAddresses"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServiceServer).ScanAheadWalletAddresses(ctx, req.(*ScanAheadWalletAddressesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletService_TransactionNotifications_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TransactionNotificationsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(WalletServiceServer).TransactionNotifications(m, &walletServiceTransactionNotificationsServer{stream})
+}
+
+type walletServiceTransactionNotificationsServer struct {
+	grpc.ServerStream
+}
+
+func (s *walletServiceTransactionNotificationsServer) Send(n *TransactionNotification) error {
+	return s.ServerStream.SendMsg(n)
+}
+
+var _WalletService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "walletrpc.WalletService",
+	HandlerType: (*WalletServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "CreateWallet", Handler: _WalletService_CreateWallet_Handler},
+		{MethodName: "EncryptWallet", Handler: _WalletService_EncryptWallet_Handler},
+		{MethodName: "UnlockWallet", Handler: _WalletService_UnlockWallet_Handler},
+		{MethodName: "NewAddresses", Handler: _WalletService_NewAddresses_Handler},
+		{MethodName: "GetAddresses", Handler: _WalletService_GetAddresses_Handler},
+		{MethodName: "GetWallets", Handler: _WalletService_GetWallets_Handler},
+		{MethodName: "SignTransaction", Handler: _WalletService_SignTransaction_Handler},
+		{MethodName: "ScanAheadWalletAddresses", Handler: _WalletService_ScanAheadWalletAddresses_Handler},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "TransactionNotifications",
+			Handler:       _WalletService_TransactionNotifications_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "walletrpc.proto",
+}
@@ -0,0 +1,212 @@
+// Package walletrpc exposes wallet.Service over gRPC, for consumers that
+// want to integrate with a skycoin node without going through the JSON
+// HTTP API in src/util/http.
+package walletrpc
+
+import (
+	"time"
+
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/visor/blockdb"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+// Server implements WalletServiceServer on top of a wallet.Service
+type Server struct {
+	Wallets   *wallet.Service
+	Balances  wallet.BalanceGetter
+	Unspents  blockdb.UnspentGetter
+	Validator wallet.Validator
+}
+
+// CreateWallet creates a new wallet
+func (s *Server) CreateWallet(ctx context.Context, req *CreateWalletRequest) (*Wallet, error) {
+	w, err := s.Wallets.CreateWallet("", wallet.Options{
+		Type:  req.Type,
+		Label: req.Label,
+		Seed:  req.Seed,
+		XPub:  req.Xpub,
+	})
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return toRPCWallet(w), nil
+}
+
+// EncryptWallet encrypts a wallet with the given password
+func (s *Server) EncryptWallet(ctx context.Context, req *EncryptWalletRequest) (*Wallet, error) {
+	w, err := s.Wallets.Encrypt(req.WalletId, req.Password)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return toRPCWallet(*w), nil
+}
+
+// UnlockWallet decrypts a wallet's secrets into memory for timeoutSeconds
+func (s *Server) UnlockWallet(ctx context.Context, req *UnlockWalletRequest) (*UnlockWalletResponse, error) {
+	timeout := time.Duration(req.TimeoutSeconds) * time.Second
+	if err := s.Wallets.Unlock(req.WalletId, req.Password, timeout); err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &UnlockWalletResponse{}, nil
+}
+
+// NewAddresses generates num new addresses in a wallet
+func (s *Server) NewAddresses(ctx context.Context, req *NewAddressesRequest) (*NewAddressesResponse, error) {
+	addrs, err := s.Wallets.NewAddresses(req.WalletId, req.Num)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &NewAddressesResponse{Addresses: addressStrings(addrs)}, nil
+}
+
+// GetAddresses returns all addresses in a wallet
+func (s *Server) GetAddresses(ctx context.Context, req *GetAddressesRequest) (*GetAddressesResponse, error) {
+	addrs, err := s.Wallets.GetAddresses(req.WalletId)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &GetAddressesResponse{Addresses: addressStrings(addrs)}, nil
+}
+
+// GetWallets returns every wallet known to the service
+func (s *Server) GetWallets(ctx context.Context, req *GetWalletsRequest) (*GetWalletsResponse, error) {
+	wlts := s.Wallets.GetWallets()
+
+	resp := &GetWalletsResponse{Wallets: make([]*Wallet, 0, len(wlts))}
+	for _, w := range wlts {
+		resp.Wallets = append(resp.Wallets, toRPCWallet(*w))
+	}
+
+	return resp, nil
+}
+
+// SignTransaction creates and signs a transaction spending coins to dest_address
+func (s *Server) SignTransaction(ctx context.Context, req *SignTransactionRequest) (*SignTransactionResponse, error) {
+	if s.Unspents == nil || s.Validator == nil {
+		return nil, status.Error(codes.Unavailable, "wallet rpc: Unspents/Validator is not configured")
+	}
+
+	dest, err := cipher.DecodeBase58Address(req.DestAddress)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	tx, err := s.Wallets.CreateAndSignTransaction(req.WalletId, s.Validator, s.Unspents, req.HeadTime, req.Coins, dest)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &SignTransactionResponse{Transaction: tx.Serialize()}, nil
+}
+
+// ScanAheadWalletAddresses scans a wallet's addresses against Balances
+func (s *Server) ScanAheadWalletAddresses(ctx context.Context, req *ScanAheadWalletAddressesRequest) (*Wallet, error) {
+	if s.Balances == nil {
+		return nil, status.Error(codes.Unavailable, "wallet rpc: Balances is not configured")
+	}
+
+	w, err := s.Wallets.ScanAheadWalletAddresses(req.WalletId, req.ScanN, s.Balances)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return toRPCWallet(w), nil
+}
+
+// transactionPollInterval is how often TransactionNotifications checks
+// wltID's addresses for a balance change while streaming
+const transactionPollInterval = 3 * time.Second
+
+// TransactionNotifications streams a notification whenever the confirmed
+// coin total of wltID's addresses, as listed when the stream opened, differs
+// from the previous poll (the first poll is compared against zero), until
+// the client disconnects or cancels. Hours and predicted balances are not
+// compared, and Txid and Height of each notification are left empty.
+func (s *Server) TransactionNotifications(req *TransactionNotificationsRequest, stream WalletService_TransactionNotificationsServer) error {
+	if s.Balances == nil {
+		return status.Error(codes.Unavailable, "wallet rpc: Balances is not configured")
+	}
+
+	ctx := stream.Context()
+
+	addrs, err := s.Wallets.GetAddresses(req.WalletId)
+	if err != nil {
+		return toStatusError(err)
+	}
+
+	var lastCoins uint64
+	ticker := time.NewTicker(transactionPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			balances, err := s.Balances.GetBalanceOfAddrs(addrs)
+			if err != nil {
+				return status.Error(codes.Internal, err.Error())
+			}
+
+			var coins uint64
+			for _, b := range balances {
+				coins += b.Confirmed.Coins
+			}
+
+			if coins == lastCoins {
+				continue
+			}
+			lastCoins = coins
+
+			if err := stream.Send(&TransactionNotification{}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func toRPCWallet(w wallet.Wallet) *Wallet {
+	return &Wallet{
+		Id:        w.GetID(),
+		Label:     w.Label(),
+		Type:      w.Type(),
+		Addresses: addressStrings(w.GetAddresses()),
+	}
+}
+
+func addressStrings(addrs []cipher.Address) []string {
+	ss := make([]string, len(addrs))
+	for i, a := range addrs {
+		ss[i] = a.String()
+	}
+	return ss
+}
+
+// toStatusError maps wallet.Service errors to canonical gRPC codes
+func toStatusError(err error) error {
+	switch err.(type) {
+	case wallet.ErrWalletNotExist:
+		return status.Error(codes.NotFound, err.Error())
+	case wallet.WalletLockedError:
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case wallet.ErrWalletDuplicate:
+		return status.Error(codes.AlreadyExists, err.Error())
+	}
+
+	switch err {
+	case wallet.ErrWalletApiDisabled:
+		return status.Error(codes.Unavailable, err.Error())
+	}
+
+	return status.Error(codes.Internal, err.Error())
+}
@@ -0,0 +1,37 @@
+package walletrpc
+
+import (
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+type unmappedError struct{}
+
+func (unmappedError) Error() string { return "unmapped" }
+
+func TestToStatusError(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want codes.Code
+	}{
+		{"not exist", wallet.ErrWalletNotExist{}, codes.NotFound},
+		{"locked", wallet.WalletLockedError{}, codes.FailedPrecondition},
+		{"duplicate", wallet.ErrWalletDuplicate{}, codes.AlreadyExists},
+		{"api disabled", wallet.ErrWalletApiDisabled, codes.Unavailable},
+		{"unmapped", unmappedError{}, codes.Internal},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := status.Code(toStatusError(c.err))
+			if got != c.want {
+				t.Errorf("toStatusError(%v) code = %v, want %v", c.err, got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,14 @@
+package wallet
+
+// Balance represents the coin and hour balance of an address or wallet
+type Balance struct {
+	Coins uint64
+	Hours uint64
+}
+
+// BalancePair records an address' confirmed balance, and its predicted
+// balance after unconfirmed transactions are applied
+type BalancePair struct {
+	Confirmed Balance
+	Predicted Balance
+}
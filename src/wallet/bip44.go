@@ -0,0 +1,129 @@
+package wallet
+
+import (
+	"fmt"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/go-bip32"
+	"github.com/skycoin/skycoin/src/cipher/go-bip39"
+)
+
+// BIP44 derivation constants for skycoin, following m/44'/coin'/account'/change/index
+const (
+	bip44Purpose  = 44
+	bip44CoinType = 8000
+	bip44Account  = 0
+)
+
+const (
+	bip44ExternalChain uint32 = 0
+	bip44ChangeChain   uint32 = 1
+)
+
+func newBIP44Wallet(wltName string, options Options) (*Wallet, error) {
+	if options.Seed == "" {
+		return nil, ErrMissingSeed
+	}
+
+	w := &Wallet{
+		Meta: map[string]string{
+			"filename":  wltName,
+			"label":     options.Label,
+			"seed":      options.Seed,
+			"type":      WalletTypeBIP44,
+			"version":   Version,
+			"coin":      "skycoin",
+			"bip44Coin": fmt.Sprintf("%d", bip44CoinType),
+			"account":   fmt.Sprintf("%d", bip44Account),
+		},
+	}
+
+	return w, nil
+}
+
+// bip44Root derives the account-level extended key m/44'/8000'/account'
+func (w *Wallet) bip44Root() (*bip32.ExtendedKey, error) {
+	seed := bip39.MnemonicToSeed(w.seed())
+
+	master, err := bip32.NewMasterKey(seed)
+	if err != nil {
+		return nil, err
+	}
+
+	purpose, err := master.NewChildKey(bip32.FirstHardenedChild + bip44Purpose)
+	if err != nil {
+		return nil, err
+	}
+
+	coinType, err := purpose.NewChildKey(bip32.FirstHardenedChild + bip44CoinType)
+	if err != nil {
+		return nil, err
+	}
+
+	return coinType.NewChildKey(bip32.FirstHardenedChild + bip44Account)
+}
+
+// generateBIP44Addresses derives the next num addresses on the external
+// chain (or the change chain, if change is true), continuing from the
+// highest existing ChildNumber on that chain
+func (w *Wallet) generateBIP44Addresses(num uint64, change bool) ([]cipher.Address, error) {
+	root, err := w.bip44Root()
+	if err != nil {
+		return nil, err
+	}
+
+	chain := bip44ExternalChain
+	if change {
+		chain = bip44ChangeChain
+	}
+
+	chainKey, err := root.NewChildKey(chain)
+	if err != nil {
+		return nil, err
+	}
+
+	next := w.nextChildNumber(change)
+
+	addrs := make([]cipher.Address, 0, num)
+	for i := uint64(0); i < num; i++ {
+		childKey, err := chainKey.NewChildKey(next)
+		if err != nil {
+			return nil, err
+		}
+
+		sk := cipher.NewSecKey(childKey.Key)
+		pk := cipher.PubKeyFromSecKey(sk)
+		addr := cipher.AddressFromPubKey(pk)
+
+		w.Entries = append(w.Entries, Entry{
+			Address:     addr,
+			Public:      pk,
+			Secret:      sk,
+			ChildNumber: next,
+			Change:      change,
+		})
+		addrs = append(addrs, addr)
+
+		next++
+	}
+
+	return addrs, nil
+}
+
+func (w *Wallet) nextChildNumber(change bool) uint32 {
+	var max uint32
+	var found bool
+	for _, e := range w.Entries {
+		if e.Change != change {
+			continue
+		}
+		if !found || e.ChildNumber >= max {
+			max = e.ChildNumber
+			found = true
+		}
+	}
+	if !found {
+		return 0
+	}
+	return max + 1
+}
@@ -0,0 +1,46 @@
+package wallet
+
+import (
+	"fmt"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// newCollectionWallet creates an empty wallet that holds independently
+// imported keypairs. It has no seed and no derivation path, so
+// GenerateAddresses is disabled; entries are added one at a time via
+// Service.ImportPrivateKey.
+func newCollectionWallet(wltName string, options Options) (*Wallet, error) {
+	w := &Wallet{
+		Meta: map[string]string{
+			"filename": wltName,
+			"label":    options.Label,
+			"type":     WalletTypeCollection,
+			"version":  Version,
+			"coin":     "skycoin",
+		},
+	}
+
+	return w, nil
+}
+
+// importPrivateKey adds sk as a new entry, returning an error if its address
+// is already present
+func (w *Wallet) importPrivateKey(sk cipher.SecKey) (cipher.Address, error) {
+	pk := cipher.PubKeyFromSecKey(sk)
+	addr := cipher.AddressFromPubKey(pk)
+
+	for _, e := range w.Entries {
+		if e.Address == addr {
+			return cipher.Address{}, fmt.Errorf("address %s is already in wallet %s", addr, w.GetID())
+		}
+	}
+
+	w.Entries = append(w.Entries, Entry{
+		Address: addr,
+		Public:  pk,
+		Secret:  sk,
+	})
+
+	return addr, nil
+}
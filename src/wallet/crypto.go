@@ -0,0 +1,54 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"math/rand"
+	"path/filepath"
+
+	"github.com/skycoin/skycoin/src/cipher/go-scrypt-chacha20poly1305"
+)
+
+// Encrypt encrypts data with password using scrypt-chacha20poly1305, the
+// same crypto type used by the existing encrypted wallet format
+func Encrypt(data, password []byte) ([]byte, error) {
+	return scryptchacha20poly1305.Encrypt(data, password)
+}
+
+// Decrypt decrypts data encrypted by Encrypt
+func Decrypt(data, password []byte) ([]byte, error) {
+	return scryptchacha20poly1305.Decrypt(data, password)
+}
+
+func loadWalletFile(path string) (*Wallet, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var w Wallet
+	if err := json.Unmarshal(data, &w); err != nil {
+		return nil, err
+	}
+
+	if w.Meta["filename"] == "" {
+		w.Meta["filename"] = filepath.Base(path)
+	}
+
+	return &w, nil
+}
+
+func saveWalletFile(path string, w *Wallet) error {
+	data, err := json.MarshalIndent(w, "", "    ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0600)
+}
+
+func randSuffix() string {
+	b := make([]byte, 4)
+	rand.Read(b) //nolint:errcheck
+	return hex.EncodeToString(b)
+}
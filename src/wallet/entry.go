@@ -0,0 +1,18 @@
+package wallet
+
+import (
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// Entry represents the spendable information of a wallet address. Its
+// Secret is populated for deterministic, bip44 and collection wallets;
+// xpub wallets leave Secret zeroed, and entries of an encrypted wallet
+// leave Secret zeroed and carry an EncryptedSeckey instead.
+type Entry struct {
+	Address         cipher.Address
+	Public          cipher.PubKey
+	Secret          cipher.SecKey
+	ChildNumber     uint32 // bip44 and xpub address index, unused for other wallet types
+	Change          bool   // bip44 change chain flag, unused for other wallet types
+	EncryptedSeckey []byte
+}
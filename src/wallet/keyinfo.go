@@ -0,0 +1,160 @@
+package wallet
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// KeyInfoVersion is the current KeyInfo format version
+const KeyInfoVersion = 1
+
+// KeyInfo is a portable, file-format-independent container for a wallet's
+// key material, used by Service.ExportWallet/Service.ImportWallet to move
+// wallets between skycoin nodes and other tools. It is serialized as JSON
+// and armored as base64 for copy-pasting. There's no top-level private key
+// field: a deterministic wallet's key material is its Seed/LastSeed, an
+// xpub wallet has none, and a collection wallet has many keys, packed into
+// Meta["keys"] (see collectionKeyInfo) rather than forced into a single
+// field.
+type KeyInfo struct {
+	Version  int
+	Type     string
+	Seed     string
+	LastSeed string
+	Meta     map[string]string
+}
+
+// collectionKeyInfo is the shape of each entry in a collection wallet's
+// Meta["keys"] blob, nested because a collection wallet has many
+// independent keys rather than the single seed or xpub other types export
+type collectionKeyInfo struct {
+	Type       string
+	PrivateKey string // hex-encoded
+}
+
+func newKeyInfo(w *Wallet) (*KeyInfo, error) {
+	ki := &KeyInfo{
+		Version: KeyInfoVersion,
+		Type:    w.Type(),
+		Meta:    make(map[string]string),
+	}
+
+	switch w.Type() {
+	case WalletTypeDeterministic, WalletTypeBIP44:
+		ki.Seed = w.seed()
+		ki.LastSeed = w.lastSeed()
+
+	case WalletTypeXPub:
+		ki.Meta["publicKey"] = w.Meta["xpub"]
+
+	case WalletTypeCollection:
+		keys := make([]collectionKeyInfo, len(w.Entries))
+		for i, e := range w.Entries {
+			keys[i] = collectionKeyInfo{
+				Type:       "secp256k1",
+				PrivateKey: hex.EncodeToString(e.Secret[:]),
+			}
+		}
+
+		blob, err := json.Marshal(keys)
+		if err != nil {
+			return nil, err
+		}
+		ki.Meta["keys"] = string(blob)
+
+	default:
+		return nil, ErrWalletTypeNotSupported
+	}
+
+	return ki, nil
+}
+
+// EncodeKeyInfo serializes ki as JSON and armors it as base64
+func EncodeKeyInfo(ki *KeyInfo) (string, error) {
+	data, err := json.Marshal(ki)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// DecodeKeyInfo reverses EncodeKeyInfo
+func DecodeKeyInfo(armored string) (*KeyInfo, error) {
+	data, err := base64.StdEncoding.DecodeString(armored)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key info: %v", err)
+	}
+
+	var ki KeyInfo
+	if err := json.Unmarshal(data, &ki); err != nil {
+		return nil, fmt.Errorf("invalid key info: %v", err)
+	}
+
+	return &ki, nil
+}
+
+// walletFromKeyInfo rebuilds a Wallet from ki. Collection wallets come back
+// with their imported entries; seed- and xpub-derived wallets come back with
+// no entries, so callers generate the first address themselves before
+// deduping it against firstAddrIDMap
+func walletFromKeyInfo(wltName, label string, ki *KeyInfo) (*Wallet, error) {
+	switch ki.Type {
+	case WalletTypeDeterministic, WalletTypeBIP44:
+		w, err := NewWallet(wltName, Options{
+			Type:  ki.Type,
+			Label: label,
+			Seed:  ki.Seed,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if ki.LastSeed != "" {
+			w.setLastSeed(ki.LastSeed)
+		}
+		return w, nil
+
+	case WalletTypeXPub:
+		return NewWallet(wltName, Options{
+			Type:  WalletTypeXPub,
+			Label: label,
+			XPub:  ki.Meta["publicKey"],
+		})
+
+	case WalletTypeCollection:
+		w, err := NewWallet(wltName, Options{
+			Type:  WalletTypeCollection,
+			Label: label,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		var keys []collectionKeyInfo
+		if err := json.Unmarshal([]byte(ki.Meta["keys"]), &keys); err != nil {
+			return nil, fmt.Errorf("invalid collection key info: %v", err)
+		}
+
+		for _, k := range keys {
+			skBytes, err := hex.DecodeString(k.PrivateKey)
+			if err != nil {
+				return nil, fmt.Errorf("invalid private key in key info: %v", err)
+			}
+
+			var sk cipher.SecKey
+			copy(sk[:], skBytes)
+
+			if _, err := w.importPrivateKey(sk); err != nil {
+				return nil, err
+			}
+		}
+
+		return w, nil
+
+	default:
+		return nil, ErrWalletTypeNotSupported
+	}
+}
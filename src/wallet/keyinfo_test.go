@@ -0,0 +1,77 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func TestKeyInfoDeterministicRoundTrip(t *testing.T) {
+	w, err := NewWallet("test.wlt", Options{
+		Type: WalletTypeDeterministic,
+		Seed: "test seed for key info round trip",
+	})
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+
+	ki, err := newKeyInfo(w)
+	if err != nil {
+		t.Fatalf("newKeyInfo: %v", err)
+	}
+
+	armored, err := EncodeKeyInfo(ki)
+	if err != nil {
+		t.Fatalf("EncodeKeyInfo: %v", err)
+	}
+
+	decoded, err := DecodeKeyInfo(armored)
+	if err != nil {
+		t.Fatalf("DecodeKeyInfo: %v", err)
+	}
+
+	if decoded.Seed != ki.Seed {
+		t.Errorf("decoded seed = %q, want %q", decoded.Seed, ki.Seed)
+	}
+	if decoded.Type != ki.Type {
+		t.Errorf("decoded type = %q, want %q", decoded.Type, ki.Type)
+	}
+
+	restored, err := walletFromKeyInfo("restored.wlt", "restored", decoded)
+	if err != nil {
+		t.Fatalf("walletFromKeyInfo: %v", err)
+	}
+	if restored.seed() != w.seed() {
+		t.Errorf("restored seed = %q, want %q", restored.seed(), w.seed())
+	}
+}
+
+func TestKeyInfoCollectionRoundTrip(t *testing.T) {
+	w, err := NewWallet("collection.wlt", Options{Type: WalletTypeCollection})
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+
+	_, sk := cipher.GenerateKeyPair()
+	addr, err := w.importPrivateKey(sk)
+	if err != nil {
+		t.Fatalf("importPrivateKey: %v", err)
+	}
+
+	ki, err := newKeyInfo(w)
+	if err != nil {
+		t.Fatalf("newKeyInfo: %v", err)
+	}
+
+	restored, err := walletFromKeyInfo("restored.wlt", "restored", ki)
+	if err != nil {
+		t.Fatalf("walletFromKeyInfo: %v", err)
+	}
+
+	if len(restored.Entries) != 1 {
+		t.Fatalf("restored wallet has %d entries, want 1", len(restored.Entries))
+	}
+	if restored.Entries[0].Address != addr {
+		t.Errorf("restored address = %v, want %v", restored.Entries[0].Address, addr)
+	}
+}
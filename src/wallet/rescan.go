@@ -0,0 +1,216 @@
+package wallet
+
+import (
+	"errors"
+	"sync"
+)
+
+// rescanBatchSize caps how many addresses a single iteration of a
+// background rescan generates and checks before committing them under the
+// service lock
+const rescanBatchSize = 20
+
+// ErrRescanNotExist is returned when a rescan id was never issued by
+// StartRescan. Finished rescans stay known, with their final Progress.
+var ErrRescanNotExist = errors.New("rescan doesn't exist")
+
+// Progress reports a background rescan's status
+type Progress struct {
+	Height           uint64
+	AddressesScanned uint64
+	AddressesTotal   uint64
+	Done             bool
+	Err              error
+}
+
+// rescanJob tracks one in-flight rescan
+type rescanJob struct {
+	mu       sync.Mutex
+	progress Progress
+	updates  chan Progress
+	cancel   chan struct{}
+}
+
+func newRescanJob(scanN uint64) *rescanJob {
+	return &rescanJob{
+		progress: Progress{AddressesTotal: scanN},
+		updates:  make(chan Progress, 1),
+		cancel:   make(chan struct{}),
+	}
+}
+
+func (j *rescanJob) update(scanned uint64) {
+	j.mu.Lock()
+	j.progress.AddressesScanned = scanned
+	p := j.progress
+	j.mu.Unlock()
+	j.publish(p)
+}
+
+func (j *rescanJob) finish(err error) {
+	j.mu.Lock()
+	j.progress.Done = true
+	j.progress.Err = err
+	p := j.progress
+	j.mu.Unlock()
+	j.publish(p)
+}
+
+func (j *rescanJob) publish(p Progress) {
+	select {
+	case j.updates <- p:
+	default:
+		// nobody has drained the previous update yet; discard it and send
+		// this newer one. RescanStatus always has the latest progress
+		// regardless
+		select {
+		case <-j.updates:
+		default:
+		}
+		j.updates <- p
+	}
+}
+
+func (j *rescanJob) status() Progress {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.progress
+}
+
+// RescanManager runs wallet address scans in background goroutines, so that
+// a large scanN does not hold Service's lock for the duration of the scan.
+type RescanManager struct {
+	mu   sync.Mutex
+	jobs map[string]*rescanJob
+}
+
+func newRescanManager() *RescanManager {
+	return &RescanManager{
+		jobs: make(map[string]*rescanJob),
+	}
+}
+
+func (rm *RescanManager) add(rescanID string, job *rescanJob) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.jobs[rescanID] = job
+}
+
+func (rm *RescanManager) get(rescanID string) (*rescanJob, bool) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	j, ok := rm.jobs[rescanID]
+	return j, ok
+}
+
+// StartRescan launches a background scan of scanN addresses in wltID against
+// bg, returning an id that RescanStatus and CancelRescan accept
+func (serv *Service) StartRescan(wltID string, scanN uint64, bg BalanceGetter) (string, error) {
+	serv.mu.RLock()
+	_, ok := serv.wallets.Get(wltID)
+	serv.mu.RUnlock()
+	if !ok {
+		return "", ErrWalletNotExist{wltID}
+	}
+
+	rescanID := NewWalletFilename()
+	job := newRescanJob(scanN)
+	serv.rescans.add(rescanID, job)
+
+	go serv.runRescan(job, wltID, scanN, bg)
+
+	return rescanID, nil
+}
+
+// RescanStatus returns the latest progress of rescanID
+func (serv *Service) RescanStatus(rescanID string) (Progress, error) {
+	job, ok := serv.rescans.get(rescanID)
+	if !ok {
+		return Progress{}, ErrRescanNotExist
+	}
+	return job.status(), nil
+}
+
+// CancelRescan stops rescanID at its next batch boundary
+func (serv *Service) CancelRescan(rescanID string) {
+	job, ok := serv.rescans.get(rescanID)
+	if !ok {
+		return
+	}
+
+	select {
+	case <-job.cancel:
+		// already cancelled
+	default:
+		close(job.cancel)
+	}
+}
+
+// runRescan advances a rescan in small batches, only holding serv.mu for the
+// brief moment it takes to append newly-discovered entries and save, so
+// NewAddresses and GetWallet calls on other wallets are never blocked for
+// the duration of a large scanN
+func (serv *Service) runRescan(job *rescanJob, wltID string, scanN uint64, bg BalanceGetter) {
+	var scanned uint64
+	for scanned < scanN {
+		select {
+		case <-job.cancel:
+			job.finish(errors.New("rescan cancelled"))
+			return
+		default:
+		}
+
+		n := uint64(rescanBatchSize)
+		if remaining := scanN - scanned; remaining < n {
+			n = remaining
+		}
+
+		serv.mu.RLock()
+		w, ok := serv.wallets.Get(wltID)
+		if !ok {
+			serv.mu.RUnlock()
+			job.finish(ErrWalletNotExist{wltID})
+			return
+		}
+		scratch := w.clone()
+		baseLen := len(scratch.Entries)
+		serv.mu.RUnlock()
+
+		// GetBalanceOfAddrs is the slow part of a scan; run it without
+		// holding the service lock at all
+		if err := scratch.ScanAddresses(n, bg); err != nil {
+			job.finish(err)
+			return
+		}
+
+		serv.mu.Lock()
+		w, ok = serv.wallets.Get(wltID)
+		if !ok {
+			serv.mu.Unlock()
+			job.finish(ErrWalletNotExist{wltID})
+			return
+		}
+		if len(w.Entries) != baseLen {
+			// NewAddresses (or another concurrent rescan) grew the live
+			// wallet while this batch scanned unlocked, so scratch was
+			// cloned from entries that are now stale; merging it in would
+			// discard whatever was added in between. Retry the batch
+			// against the current state instead of overwriting it.
+			serv.mu.Unlock()
+			continue
+		}
+		w.Entries = append(w.Entries, scratch.Entries[baseLen:]...)
+		w.setLastSeed(scratch.lastSeed())
+		err := w.Save(serv.WalletDirectory)
+		serv.mu.Unlock()
+		if err != nil {
+			job.finish(err)
+			return
+		}
+
+		scanned += n
+		job.update(scanned)
+	}
+
+	job.finish(nil)
+}
@@ -0,0 +1,104 @@
+package wallet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// fakeBalanceGetter reports every address as holding a balance, optionally
+// after a delay, so tests can widen the window runRescan spends scanning
+// without the service lock held.
+type fakeBalanceGetter struct {
+	delay time.Duration
+}
+
+func (f fakeBalanceGetter) GetBalanceOfAddrs(addrs []cipher.Address) ([]BalancePair, error) {
+	if f.delay > 0 {
+		time.Sleep(f.delay)
+	}
+	bals := make([]BalancePair, len(addrs))
+	for i := range bals {
+		bals[i].Confirmed.Coins = 1
+	}
+	return bals, nil
+}
+
+func TestRescanNotExist(t *testing.T) {
+	serv, cleanup := newTestService(t)
+	defer cleanup()
+
+	if _, err := serv.StartRescan("does not exist", 1, fakeBalanceGetter{}); err == nil {
+		t.Fatalf("StartRescan on an unknown wallet should fail")
+	}
+
+	if _, err := serv.RescanStatus("does not exist"); err != ErrRescanNotExist {
+		t.Fatalf("RescanStatus on an unknown id = %v, want ErrRescanNotExist", err)
+	}
+
+	// CancelRescan on an unknown id is a silent no-op
+	serv.CancelRescan("does not exist")
+}
+
+// TestRescanDoesNotDropConcurrentAddresses is a regression test for
+// runRescan silently overwriting the live wallet's entries with a clone
+// taken before an in-flight NewAddresses call committed.
+func TestRescanDoesNotDropConcurrentAddresses(t *testing.T) {
+	serv, cleanup := newTestService(t)
+	defer cleanup()
+
+	wltID := firstWalletID(t, serv)
+
+	baseline, err := serv.GetWallet(wltID)
+	if err != nil {
+		t.Fatalf("GetWallet: %v", err)
+	}
+	baseLen := len(baseline.Entries)
+
+	const scanN = 5
+	bg := fakeBalanceGetter{delay: 20 * time.Millisecond}
+
+	rescanID, err := serv.StartRescan(wltID, scanN, bg)
+	if err != nil {
+		t.Fatalf("StartRescan: %v", err)
+	}
+
+	// Race a NewAddresses call against the in-flight rescan batch, which
+	// is sleeping in GetBalanceOfAddrs without the service lock held.
+	if _, err := serv.NewAddresses(wltID, 1); err != nil {
+		t.Fatalf("NewAddresses: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	var progress Progress
+	for time.Now().Before(deadline) {
+		progress, err = serv.RescanStatus(rescanID)
+		if err != nil {
+			t.Fatalf("RescanStatus: %v", err)
+		}
+		if progress.Done {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if !progress.Done {
+		t.Fatalf("rescan did not finish within the test deadline")
+	}
+	if progress.Err != nil {
+		t.Fatalf("rescan failed: %v", progress.Err)
+	}
+
+	final, err := serv.GetWallet(wltID)
+	if err != nil {
+		t.Fatalf("GetWallet: %v", err)
+	}
+
+	// Both the concurrently-added address and the scanned addresses must
+	// still be present; overwriting the live entries with a stale clone
+	// would have dropped one or the other.
+	want := baseLen + 1 + scanN
+	if len(final.Entries) < want {
+		t.Fatalf("wallet has %d entries after rescan, want at least %d (concurrent NewAddresses entry was dropped)", len(final.Entries), want)
+	}
+}
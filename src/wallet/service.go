@@ -1,11 +1,13 @@
 package wallet
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/cipher/go-bip39"
@@ -13,8 +15,7 @@ import (
 	"github.com/skycoin/skycoin/src/visor/blockdb"
 )
 
-// ErrWalletNotExist is returned if a wallet does not exist
-var ErrWalletNotExist = errors.New("wallet doesn't exist")
+// ErrWalletApiDisabled is returned when the wallet API is disabled
 var ErrWalletApiDisabled = errors.New("wallet api disabled")
 
 // BalanceGetter interface for getting the balance of given addresses
@@ -24,11 +25,17 @@ type BalanceGetter interface {
 
 // Service wallet service struct
 type Service struct {
-	sync.RWMutex
+	mu               sync.RWMutex
 	wallets          Wallets
 	firstAddrIDMap   map[string]string // key: first address in wallet, value: wallet id
 	disableWalletAPI bool
 	WalletDirectory  string
+	unlocked         map[string]*Wallet    // decrypted copies of locked wallets, keyed by wallet id
+	unlockTimers     map[string]*time.Timer // zeroizes and evicts the matching entry in unlocked on expiry
+	unlockGen        map[string]uint64      // bumped on every Unlock/Lock, so a stale timer callback can no-op
+	rescans          *RescanManager
+	syncedListeners  []chan struct{} // registered by GetSyncedUpdate, closed and dropped by NotifySynced
+	lastSyncedHeight uint64
 }
 
 // NewService new wallet service
@@ -36,6 +43,10 @@ func NewService(walletDir string, disableWalletAPI bool) (*Service, error) {
 	serv := &Service{
 		disableWalletAPI: disableWalletAPI,
 		firstAddrIDMap:   make(map[string]string),
+		unlocked:         make(map[string]*Wallet),
+		unlockTimers:     make(map[string]*time.Timer),
+		unlockGen:        make(map[string]uint64),
+		rescans:          newRescanManager(),
 	}
 	if serv.disableWalletAPI {
 		return serv, nil
@@ -78,8 +89,8 @@ func NewService(walletDir string, disableWalletAPI bool) (*Service, error) {
 
 // CreateWallet creates a wallet with one address
 func (serv *Service) CreateWallet(wltName string, options Options) (Wallet, error) {
-	serv.Lock()
-	defer serv.Unlock()
+	serv.mu.Lock()
+	defer serv.mu.Unlock()
 	if serv.disableWalletAPI {
 		return Wallet{}, ErrWalletApiDisabled
 	}
@@ -93,8 +104,8 @@ func (serv *Service) CreateWallet(wltName string, options Options) (Wallet, erro
 // ScanAheadWalletAddresses scans n addresses for a balance, and sets the wallet's entry list to the highest
 // address with a non-zero coins balance.
 func (serv *Service) ScanAheadWalletAddresses(wltName string, scanN uint64, bg BalanceGetter) (Wallet, error) {
-	serv.Lock()
-	defer serv.Unlock()
+	serv.mu.Lock()
+	defer serv.mu.Unlock()
 
 	w, err := serv.getWallet(wltName)
 	if err != nil {
@@ -105,34 +116,42 @@ func (serv *Service) ScanAheadWalletAddresses(wltName string, scanN uint64, bg B
 		return Wallet{}, err
 	}
 
-	if err := Save(serv.WalletDirectory); err != nil {
+	if err := Save(serv.WalletDirectory, &w); err != nil {
 		return Wallet{}, err
 	}
 
-	serv.wallets.set(w)
+	serv.wallets.set(&w)
 
 	return w.Copy(), nil
 }
 
-// loadWallet loads wallet from seed and scan the first N addresses
+// loadWallet loads a wallet of the type requested in options and scans the
+// first N addresses. Collection wallets start with no entries and are never
+// scanned, since they have no derivation path to generate candidates from.
 func (serv *Service) loadWallet(wltName string, options Options, scanN uint64, bg BalanceGetter) (Wallet, error) {
 	w, err := NewWallet(wltName, options)
 	if err != nil {
 		return Wallet{}, err
 	}
 
-	// Generate a default address
-	w.GenerateAddresses(1)
+	if w.Type() != WalletTypeCollection {
+		// Generate a default address
+		if _, err := w.GenerateAddresses(1); err != nil {
+			return Wallet{}, err
+		}
 
-	// Check for duplicate wallets by initial seed
-	if id, ok := serv.firstAddrIDMap[w.Entries[0].Address.String()]; ok {
-		return Wallet{}, fmt.Errorf("duplicate wallet with %v", id)
-	}
+		// Check for duplicate wallets by initial address
+		if id, ok := serv.firstAddrIDMap[w.Entries[0].Address.String()]; ok {
+			return Wallet{}, ErrWalletDuplicate{id}
+		}
 
-	// Scan for addresses with balances
-	if scanN > 1 && bg != nil {
-		if err := w.ScanAddresses(scanN-1, bg); err != nil {
-			return Wallet{}, err
+		// Scan for addresses with balances. xpub wallets have no private key
+		// to derive the hidden change chain, so they only scan what
+		// GenerateAddresses can produce from the external chain.
+		if scanN > 1 && bg != nil {
+			if err := w.ScanAddresses(scanN-1, bg); err != nil {
+				return Wallet{}, err
+			}
 		}
 	}
 
@@ -146,11 +165,59 @@ func (serv *Service) loadWallet(wltName string, options Options, scanN uint64, b
 		return Wallet{}, err
 	}
 
-	serv.firstAddrIDMap[w.Entries[0].Address.String()] = w.Filename()
+	if len(w.Entries) > 0 {
+		serv.firstAddrIDMap[w.Entries[0].Address.String()] = w.Filename()
+	}
 
 	return w.Copy(), nil
 }
 
+// ImportXPub creates a new watch-only wallet from a serialized extended
+// public key. The wallet has no private key material and cannot sign.
+func (serv *Service) ImportXPub(xpub, label string) (Wallet, error) {
+	serv.mu.Lock()
+	defer serv.mu.Unlock()
+	if serv.disableWalletAPI {
+		return Wallet{}, ErrWalletApiDisabled
+	}
+
+	wltName := serv.generateUniqueWalletFilename()
+	return serv.loadWallet(wltName, Options{
+		Type:  WalletTypeXPub,
+		Label: label,
+		XPub:  xpub,
+	}, 0, nil)
+}
+
+// ImportPrivateKey imports sk into the collection wallet wltID
+func (serv *Service) ImportPrivateKey(wltID string, sk cipher.SecKey) (cipher.Address, error) {
+	serv.mu.Lock()
+	defer serv.mu.Unlock()
+	if serv.disableWalletAPI {
+		return cipher.Address{}, ErrWalletApiDisabled
+	}
+
+	w, ok := serv.wallets.Get(wltID)
+	if !ok {
+		return cipher.Address{}, ErrWalletNotExist{wltID}
+	}
+
+	if w.Type() != WalletTypeCollection {
+		return cipher.Address{}, errors.New("ImportPrivateKey is only supported for collection wallets")
+	}
+
+	addr, err := w.importPrivateKey(sk)
+	if err != nil {
+		return cipher.Address{}, err
+	}
+
+	if err := w.Save(serv.WalletDirectory); err != nil {
+		return cipher.Address{}, err
+	}
+
+	return addr, nil
+}
+
 func (serv *Service) generateUniqueWalletFilename() string {
 	wltName := NewWalletFilename()
 	for {
@@ -165,31 +232,39 @@ func (serv *Service) generateUniqueWalletFilename() string {
 
 // Encrypt encrypts wallet by given password
 func (serv *Service) Encrypt(wltID, password string) (*Wallet, error) {
-	serv.Lock()
-	defer serv.Unlock()
+	serv.mu.Lock()
+	defer serv.mu.Unlock()
 	w, ok := serv.wallets.Get(wltID)
 	if !ok {
 		return nil, ErrWalletNotExist{wltID}
 	}
 
+	if !w.HasSecrets() {
+		// xpub wallets hold no private key material, so there is nothing to encrypt
+		return nil, ErrNoPrivateKey
+	}
+
 	oldVersion := w.Version()
 
 	// Update version to lastest
 	w.setVersion(Version)
+	w.setEncrypted(true)
 
-	// encrypt seed
-	sseed, err := Encrypt([]byte(w.seed()), []byte(password))
-	if err != nil {
-		return nil, err
-	}
-	w.setSeed(sseed)
+	// deterministic and bip44 wallets also encrypt their seed; collection
+	// wallets have no seed, only imported entries
+	if w.seed() != "" {
+		sseed, err := Encrypt([]byte(w.seed()), []byte(password))
+		if err != nil {
+			return nil, err
+		}
+		w.setSeed(string(sseed))
 
-	// encrypt lastSeed
-	lsseed, err := Encrypt([]byte(w.lastSeed()), []byte(password))
-	if err != nil {
-		return nil, err
+		lsseed, err := Encrypt([]byte(w.lastSeed()), []byte(password))
+		if err != nil {
+			return nil, err
+		}
+		w.setLastSeed(string(lsseed))
 	}
-	w.setLastSeed(lsseed)
 
 	// encrypts private keys in entries
 	for i := range w.Entries {
@@ -225,11 +300,176 @@ func (serv *Service) Encrypt(wltID, password string) (*Wallet, error) {
 	return nw, nil
 }
 
+// Unlock decrypts wltID's seed, lastSeed and entry secrets into an in-memory
+// copy kept separate from the encrypted-on-disk wallet, so that signing
+// doesn't need the password again until timeout elapses or Lock is called.
+func (serv *Service) Unlock(wltID, password string, timeout time.Duration) error {
+	serv.mu.Lock()
+	defer serv.mu.Unlock()
+
+	w, ok := serv.wallets.Get(wltID)
+	if !ok {
+		return ErrWalletNotExist{wltID}
+	}
+
+	if !w.HasSecrets() {
+		return ErrNoPrivateKey
+	}
+
+	uw, err := decryptWallet(w, password)
+	if err != nil {
+		return err
+	}
+
+	serv.unlocked[wltID] = uw
+	serv.resetUnlockTimer(wltID, timeout)
+
+	return nil
+}
+
+// Lock drops wltID's decrypted copy, zeroing its secrets first
+func (serv *Service) Lock(wltID string) {
+	serv.mu.Lock()
+	defer serv.mu.Unlock()
+	serv.lock(wltID)
+}
+
+// lock assumes serv.mu is already held
+func (serv *Service) lock(wltID string) {
+	serv.unlockGen[wltID]++
+
+	if t, ok := serv.unlockTimers[wltID]; ok {
+		t.Stop()
+		delete(serv.unlockTimers, wltID)
+	}
+
+	uw, ok := serv.unlocked[wltID]
+	if !ok {
+		return
+	}
+
+	// uw.seed()/lastSeed() are plain Go strings, so reassigning them to ""
+	// drops this reference but cannot scrub the original backing memory;
+	// the runtime may have copied that string's bytes during decryption,
+	// and zero has no way to reach those copies. Only the fixed-size
+	// cipher.SecKey arrays below are actually wiped in place.
+	uw.setSeed("")
+	uw.setLastSeed("")
+	for i := range uw.Entries {
+		zero(uw.Entries[i].Secret[:])
+	}
+
+	delete(serv.unlocked, wltID)
+}
+
+// resetUnlockTimer assumes serv.mu is already held
+func (serv *Service) resetUnlockTimer(wltID string, timeout time.Duration) {
+	if t, ok := serv.unlockTimers[wltID]; ok {
+		t.Stop()
+	}
+
+	serv.unlockGen[wltID]++
+	gen := serv.unlockGen[wltID]
+
+	serv.unlockTimers[wltID] = time.AfterFunc(timeout, func() {
+		serv.mu.Lock()
+		defer serv.mu.Unlock()
+		// t.Stop() cannot cancel a callback that already started running,
+		// so a re-Unlock racing with this timer's expiry must still be
+		// able to veto it; gen lets a stale firing no-op instead of
+		// locking a wallet that was unlocked again after this timer fired.
+		if serv.unlockGen[wltID] != gen {
+			return
+		}
+		serv.lock(wltID)
+	})
+}
+
+// IsLocked returns true if wltID has no decrypted copy in memory
+func (serv *Service) IsLocked(wltID string) bool {
+	serv.mu.RLock()
+	defer serv.mu.RUnlock()
+	_, ok := serv.unlocked[wltID]
+	return !ok
+}
+
+// ExportWallet returns wltID's key material as a portable KeyInfo, decrypting
+// it with password first if necessary. This gives users a way to move keys
+// between skycoin nodes and other tools without depending on the on-disk
+// wallet file format.
+func (serv *Service) ExportWallet(wltID, password string) (*KeyInfo, error) {
+	serv.mu.RLock()
+	defer serv.mu.RUnlock()
+
+	w, ok := serv.wallets.Get(wltID)
+	if !ok {
+		return nil, ErrWalletNotExist{wltID}
+	}
+
+	src := w
+	if w.IsEncrypted() {
+		if uw, ok := serv.unlocked[wltID]; ok {
+			src = uw
+		} else {
+			dw, err := decryptWallet(w, password)
+			if err != nil {
+				return nil, err
+			}
+			src = dw
+		}
+	}
+
+	return newKeyInfo(src)
+}
+
+// ImportWallet creates a new wallet named label from ki, rejecting it if its
+// derived first address collides with an existing wallet, the same way
+// loadWallet dedupes a newly created wallet.
+func (serv *Service) ImportWallet(ki *KeyInfo, label string) (Wallet, error) {
+	serv.mu.Lock()
+	defer serv.mu.Unlock()
+	if serv.disableWalletAPI {
+		return Wallet{}, ErrWalletApiDisabled
+	}
+
+	wltName := serv.generateUniqueWalletFilename()
+
+	w, err := walletFromKeyInfo(wltName, label, ki)
+	if err != nil {
+		return Wallet{}, err
+	}
+
+	if w.Type() != WalletTypeCollection {
+		if _, err := w.GenerateAddresses(1); err != nil {
+			return Wallet{}, err
+		}
+
+		if id, ok := serv.firstAddrIDMap[w.Entries[0].Address.String()]; ok {
+			return Wallet{}, ErrWalletDuplicate{id}
+		}
+	}
+
+	if err := serv.wallets.Add(*w); err != nil {
+		return Wallet{}, err
+	}
+
+	if err := w.Save(serv.WalletDirectory); err != nil {
+		serv.wallets.Remove(w.GetID())
+		return Wallet{}, err
+	}
+
+	if len(w.Entries) > 0 {
+		serv.firstAddrIDMap[w.Entries[0].Address.String()] = w.Filename()
+	}
+
+	return w.Copy(), nil
+}
+
 // NewAddresses generate address entries in given wallet,
 // return nil if wallet does not exist.
 func (serv *Service) NewAddresses(wltID string, num uint64) ([]cipher.Address, error) {
-	serv.Lock()
-	defer serv.Unlock()
+	serv.mu.Lock()
+	defer serv.mu.Unlock()
 	w, ok := serv.wallets.Get(wltID)
 	if !ok {
 		return []cipher.Address{}, ErrWalletNotExist{wltID}
@@ -240,7 +480,7 @@ func (serv *Service) NewAddresses(wltID string, num uint64) ([]cipher.Address, e
 		return nil, err
 	}
 
-	if err := Save(w, serv.WalletDirectory); err != nil {
+	if err := Save(serv.WalletDirectory, w); err != nil {
 		return nil, err
 	}
 
@@ -249,8 +489,8 @@ func (serv *Service) NewAddresses(wltID string, num uint64) ([]cipher.Address, e
 
 // GetAddresses returns all addresses in given wallet
 func (serv *Service) GetAddresses(wltID string) ([]cipher.Address, error) {
-	serv.RLock()
-	defer serv.RUnlock()
+	serv.mu.RLock()
+	defer serv.mu.RUnlock()
 	w, ok := serv.wallets.Get(wltID)
 	if !ok {
 		return []cipher.Address{}, ErrWalletNotExist{wltID}
@@ -261,8 +501,8 @@ func (serv *Service) GetAddresses(wltID string) ([]cipher.Address, error) {
 
 // GetWallet returns wallet by id
 func (serv *Service) GetWallet(wltID string) (Wallet, error) {
-	serv.RLock()
-	defer serv.RUnlock()
+	serv.mu.RLock()
+	defer serv.mu.RUnlock()
 
 	return serv.getWallet(wltID)
 }
@@ -270,15 +510,15 @@ func (serv *Service) GetWallet(wltID string) (Wallet, error) {
 func (serv *Service) getWallet(wltID string) (Wallet, error) {
 	w, ok := serv.wallets.Get(wltID)
 	if !ok {
-		return Wallet{}, ErrWalletNotExist
+		return Wallet{}, ErrWalletNotExist{wltID}
 	}
-	return w.clone(), nil
+	return *w.clone(), nil
 }
 
 // GetWallets returns all wallets
 func (serv *Service) GetWallets() Wallets {
-	serv.RLock()
-	defer serv.RUnlock()
+	serv.mu.RLock()
+	defer serv.mu.RUnlock()
 	wlts := make(Wallets, len(serv.wallets))
 	for k, w := range serv.wallets {
 		nw := w.clone()
@@ -289,8 +529,8 @@ func (serv *Service) GetWallets() Wallets {
 
 // ReloadWallets reload wallets
 func (serv *Service) ReloadWallets() error {
-	serv.Lock()
-	defer serv.Unlock()
+	serv.mu.Lock()
+	defer serv.mu.Unlock()
 	if serv.disableWalletAPI {
 		return ErrWalletApiDisabled
 	}
@@ -304,23 +544,34 @@ func (serv *Service) ReloadWallets() error {
 	return nil
 }
 
-// CreateAndSignTransaction creates and sign transaction from wallet
+// CreateAndSignTransaction creates and sign transaction from wallet. If the
+// wallet is encrypted, its unlocked (decrypted) copy is used instead, and
+// WalletLockedError is returned if Unlock hasn't been called or has expired.
 func (serv *Service) CreateAndSignTransaction(wltID string, vld Validator, unspent blockdb.UnspentGetter,
 	headTime, coins uint64, dest cipher.Address) (*coin.Transaction, error) {
-	serv.RLock()
-	defer serv.RUnlock()
+	serv.mu.RLock()
+	defer serv.mu.RUnlock()
 	w, ok := serv.wallets.Get(wltID)
 	if !ok {
 		return nil, ErrWalletNotExist{wltID}
 	}
 
-	return w.CreateAndSignTransaction(vld, unspent, headTime, coins, dest)
+	signer := w
+	if w.IsEncrypted() {
+		uw, ok := serv.unlocked[wltID]
+		if !ok {
+			return nil, WalletLockedError{wltID}
+		}
+		signer = uw
+	}
+
+	return signer.CreateAndSignTransaction(vld, unspent, headTime, coins, dest)
 }
 
 // UpdateWalletLabel updates the wallet label
 func (serv *Service) UpdateWalletLabel(wltID, label string) error {
-	serv.Lock()
-	defer serv.Unlock()
+	serv.mu.Lock()
+	defer serv.mu.Unlock()
 	var wlt *Wallet
 	if err := serv.wallets.update(wltID, func(w *Wallet) *Wallet {
 		w.setLabel(label)
@@ -335,16 +586,74 @@ func (serv *Service) UpdateWalletLabel(wltID, label string) error {
 
 // Remove removes wallet of given wallet id from the service
 func (serv *Service) Remove(wltID string) {
-	serv.Lock()
-	defer serv.Unlock()
+	serv.mu.Lock()
+	defer serv.mu.Unlock()
 	serv.wallets.Remove(wltID)
 }
 
+// GetSyncedUpdate registers a fresh channel that closes the next time
+// NotifySynced is called, or is dropped if ctx is done first. Callers
+// building on top of wallet+visor (transaction builders, RPC handlers) can
+// use this to wait for the next confirmed tip before reading balances,
+// instead of polling.
+func (serv *Service) GetSyncedUpdate(ctx context.Context) <-chan struct{} {
+	ch := make(chan struct{})
+
+	serv.mu.Lock()
+	serv.syncedListeners = append(serv.syncedListeners, ch)
+	serv.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		serv.removeSyncedListener(ch)
+	}()
+
+	return ch
+}
+
+func (serv *Service) removeSyncedListener(ch chan struct{}) {
+	serv.mu.Lock()
+	defer serv.mu.Unlock()
+	for i, c := range serv.syncedListeners {
+		if c == ch {
+			serv.syncedListeners = append(serv.syncedListeners[:i], serv.syncedListeners[i+1:]...)
+			return
+		}
+	}
+}
+
+// NotifySynced records headHeight as the synced tip and closes every
+// channel registered via GetSyncedUpdate, waking their callers. It is
+// invoked by the visor whenever a new block is applied.
+func (serv *Service) NotifySynced(headHeight uint64) {
+	serv.mu.Lock()
+	defer serv.mu.Unlock()
+
+	serv.lastSyncedHeight = headHeight
+
+	for _, ch := range serv.syncedListeners {
+		close(ch)
+	}
+	serv.syncedListeners = nil
+}
+
+// SyncedHeight returns the height of the last block NotifySynced was called with
+func (serv *Service) SyncedHeight() uint64 {
+	serv.mu.RLock()
+	defer serv.mu.RUnlock()
+	return serv.lastSyncedHeight
+}
+
 func (serv *Service) removeDup(wlts Wallets) Wallets {
 	var rmWltIDS []string
 	// remove dup wallets
 	for wltID, wlt := range wlts {
 		if len(wlt.Entries) == 0 {
+			// Collection wallets legitimately start empty, since their
+			// entries only arrive via ImportPrivateKey
+			if wlt.Type() == WalletTypeCollection {
+				continue
+			}
 			// empty wallet
 			rmWltIDS = append(rmWltIDS, wltID)
 			continue
@@ -388,3 +697,24 @@ type ErrWalletNotExist struct {
 func (ew ErrWalletNotExist) Error() string {
 	return fmt.Sprintf("wallet %s doesn't exist", ew.id)
 }
+
+// WalletLockedError is returned when a locked wallet is asked to sign
+type WalletLockedError struct {
+	id string
+}
+
+// Error returns the error message
+func (e WalletLockedError) Error() string {
+	return fmt.Sprintf("wallet %s is locked", e.id)
+}
+
+// ErrWalletDuplicate is returned when a new wallet's first address collides
+// with an existing wallet's
+type ErrWalletDuplicate struct {
+	id string
+}
+
+// Error returns the error message
+func (e ErrWalletDuplicate) Error() string {
+	return fmt.Sprintf("duplicate wallet with %v", e.id)
+}
@@ -0,0 +1,58 @@
+package wallet
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSyncedHeightAndNotify(t *testing.T) {
+	serv, cleanup := newTestService(t)
+	defer cleanup()
+
+	if h := serv.SyncedHeight(); h != 0 {
+		t.Fatalf("SyncedHeight before any NotifySynced = %d, want 0", h)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch := serv.GetSyncedUpdate(ctx)
+
+	serv.NotifySynced(42)
+
+	select {
+	case <-ch:
+	case <-time.After(time.Second):
+		t.Fatalf("GetSyncedUpdate channel was not closed by NotifySynced")
+	}
+
+	if h := serv.SyncedHeight(); h != 42 {
+		t.Fatalf("SyncedHeight after NotifySynced(42) = %d, want 42", h)
+	}
+}
+
+func TestGetSyncedUpdateRemovedOnContextCancel(t *testing.T) {
+	serv, cleanup := newTestService(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	serv.GetSyncedUpdate(ctx)
+	cancel()
+
+	// removeSyncedListener runs in its own goroutine in response to
+	// ctx.Done(), so give it a moment to run.
+	deadline := time.Now().Add(time.Second)
+	for {
+		serv.mu.RLock()
+		n := len(serv.syncedListeners)
+		serv.mu.RUnlock()
+		if n == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("syncedListeners still has %d entries after context cancel, want 0", n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,107 @@
+package wallet
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func newTestService(t *testing.T) (*Service, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "wallet_test")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	serv, err := NewService(dir, false)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("NewService: %v", err)
+	}
+	return serv, func() { os.RemoveAll(dir) }
+}
+
+func firstWalletID(t *testing.T, serv *Service) string {
+	t.Helper()
+	for id := range serv.GetWallets() {
+		return id
+	}
+	t.Fatal("no wallets found")
+	return ""
+}
+
+func TestUnlockLock(t *testing.T) {
+	serv, cleanup := newTestService(t)
+	defer cleanup()
+
+	wltID := firstWalletID(t, serv)
+
+	if _, err := serv.Encrypt(wltID, "password"); err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+
+	if !serv.IsLocked(wltID) {
+		t.Fatalf("wallet should start locked after Encrypt")
+	}
+
+	if err := serv.Unlock(wltID, "password", time.Hour); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+
+	if serv.IsLocked(wltID) {
+		t.Fatalf("wallet should be unlocked after Unlock")
+	}
+
+	serv.Lock(wltID)
+
+	if !serv.IsLocked(wltID) {
+		t.Fatalf("wallet should be locked after Lock")
+	}
+}
+
+func TestUnlockWrongPassword(t *testing.T) {
+	serv, cleanup := newTestService(t)
+	defer cleanup()
+
+	wltID := firstWalletID(t, serv)
+
+	if _, err := serv.Encrypt(wltID, "password"); err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+
+	if err := serv.Unlock(wltID, "wrong password", time.Hour); err == nil {
+		t.Fatalf("Unlock with wrong password should have failed")
+	}
+}
+
+// TestResetUnlockTimerIgnoresStaleGeneration guards against the unlock
+// timer race: a stale AfterFunc callback whose Stop() lost the race to an
+// in-flight fire must not lock a wallet that was re-Unlocked afterward.
+func TestResetUnlockTimerIgnoresStaleGeneration(t *testing.T) {
+	serv, cleanup := newTestService(t)
+	defer cleanup()
+
+	wltID := firstWalletID(t, serv)
+
+	if _, err := serv.Encrypt(wltID, "password"); err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+
+	if err := serv.Unlock(wltID, "password", time.Millisecond); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+
+	// Give the short timeout a chance to fire, racing against the second
+	// Unlock call below.
+	time.Sleep(5 * time.Millisecond)
+
+	if err := serv.Unlock(wltID, "password", time.Hour); err != nil {
+		t.Fatalf("re-Unlock: %v", err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	if serv.IsLocked(wltID) {
+		t.Fatalf("wallet should still be unlocked: a stale timer callback re-locked it")
+	}
+}
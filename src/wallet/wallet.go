@@ -0,0 +1,402 @@
+package wallet
+
+import (
+	"errors"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/visor/blockdb"
+)
+
+// Version is the current wallet file format version
+const Version = "0.2"
+
+// Wallet types supported by Service. A wallet's type is fixed at creation
+// and determines how its entries are derived and whether it can sign.
+const (
+	WalletTypeDeterministic = "deterministic"
+	WalletTypeBIP44         = "bip44"
+	WalletTypeXPub          = "xpub"
+	WalletTypeCollection    = "collection"
+)
+
+// ErrWalletTypeNotSupported is returned when Options.Type is unrecognized
+var ErrWalletTypeNotSupported = errors.New("wallet type not supported")
+
+// ErrMissingSeed is returned when creating a seed-derived wallet without a seed
+var ErrMissingSeed = errors.New("seed missing")
+
+// ErrMissingXPub is returned when creating an xpub wallet without an xpub
+var ErrMissingXPub = errors.New("xpub missing")
+
+// ErrNoPrivateKey is returned when an operation needs a private key but the
+// wallet does not hold one, e.g. signing, encrypting or unlocking an xpub
+// wallet
+var ErrNoPrivateKey = errors.New("wallet does not contain a private key")
+
+// ErrWalletCannotGenerateAddresses is returned when GenerateAddresses is
+// called on a wallet type that only holds imported, undiscoverable keys
+var ErrWalletCannotGenerateAddresses = errors.New("wallet type does not support generating new addresses")
+
+// Validator validates transactions against unconfirmed spends before they
+// are signed
+type Validator interface {
+	HasUnconfirmedSpendTx(addr []cipher.Address) (bool, error)
+}
+
+// Options are the parameters for creating a new wallet via Service.CreateWallet
+type Options struct {
+	// Type is one of WalletTypeDeterministic, WalletTypeBIP44, WalletTypeXPub or WalletTypeCollection.
+	// Defaults to WalletTypeDeterministic if empty.
+	Type string
+	Label string
+	Seed  string
+	// XPub is the serialized extended public key, only used when Type is WalletTypeXPub
+	XPub string
+}
+
+// Wallet holds a set of entries, derived or imported according to its Type.
+// Meta stores string-valued wallet metadata (label, seed, type, version, ...)
+// so that new fields can be added to the file format without bumping Version.
+type Wallet struct {
+	Meta    map[string]string
+	Entries []Entry
+}
+
+// NewWallet creates a wallet of the type named in options.Type, defaulting
+// to a single-seed deterministic wallet for backwards compatibility
+func NewWallet(wltName string, options Options) (*Wallet, error) {
+	if options.Type == "" {
+		options.Type = WalletTypeDeterministic
+	}
+
+	switch options.Type {
+	case WalletTypeDeterministic:
+		return newDeterministicWallet(wltName, options)
+	case WalletTypeBIP44:
+		return newBIP44Wallet(wltName, options)
+	case WalletTypeXPub:
+		return newXPubWallet(wltName, options)
+	case WalletTypeCollection:
+		return newCollectionWallet(wltName, options)
+	default:
+		return nil, ErrWalletTypeNotSupported
+	}
+}
+
+func newDeterministicWallet(wltName string, options Options) (*Wallet, error) {
+	if options.Seed == "" {
+		return nil, ErrMissingSeed
+	}
+
+	w := &Wallet{
+		Meta: map[string]string{
+			"filename": wltName,
+			"label":    options.Label,
+			"seed":     options.Seed,
+			"lastSeed": options.Seed,
+			"type":     WalletTypeDeterministic,
+			"version":  Version,
+			"coin":     "skycoin",
+		},
+	}
+
+	return w, nil
+}
+
+// Type returns the wallet's type, defaulting to WalletTypeDeterministic for
+// wallets loaded from files written before Type existed
+func (w *Wallet) Type() string {
+	if t := w.Meta["type"]; t != "" {
+		return t
+	}
+	return WalletTypeDeterministic
+}
+
+// IsDeterministic returns true if the wallet derives addresses from a single seed
+func (w *Wallet) IsDeterministic() bool {
+	return w.Type() == WalletTypeDeterministic
+}
+
+// HasSecrets returns true if the wallet type can hold private key material
+func (w *Wallet) HasSecrets() bool {
+	return w.Type() != WalletTypeXPub
+}
+
+func (w *Wallet) seed() string {
+	return w.Meta["seed"]
+}
+
+func (w *Wallet) setSeed(seed string) {
+	w.Meta["seed"] = seed
+}
+
+func (w *Wallet) lastSeed() string {
+	return w.Meta["lastSeed"]
+}
+
+func (w *Wallet) setLastSeed(lastSeed string) {
+	w.Meta["lastSeed"] = lastSeed
+}
+
+func (w *Wallet) setLabel(label string) {
+	w.Meta["label"] = label
+}
+
+// Label returns the wallet's label
+func (w *Wallet) Label() string {
+	return w.Meta["label"]
+}
+
+func (w *Wallet) setVersion(v string) {
+	w.Meta["version"] = v
+}
+
+// Version returns the wallet file format version
+func (w *Wallet) Version() string {
+	return w.Meta["version"]
+}
+
+// IsEncrypted returns true if the wallet's secrets are encrypted on disk
+func (w *Wallet) IsEncrypted() bool {
+	return w.Meta["encrypted"] == "true"
+}
+
+func (w *Wallet) setEncrypted(encrypted bool) {
+	if encrypted {
+		w.Meta["encrypted"] = "true"
+	} else {
+		delete(w.Meta, "encrypted")
+	}
+}
+
+// GetID returns the wallet's filename, which is used as its unique id
+func (w *Wallet) GetID() string {
+	return w.Meta["filename"]
+}
+
+// Filename returns the wallet's filename
+func (w *Wallet) Filename() string {
+	return w.Meta["filename"]
+}
+
+// GetAddresses returns all addresses in the wallet
+func (w *Wallet) GetAddresses() []cipher.Address {
+	addrs := make([]cipher.Address, len(w.Entries))
+	for i, e := range w.Entries {
+		addrs[i] = e.Address
+	}
+	return addrs
+}
+
+// GenerateAddresses generates num new addresses, deriving them according to
+// the wallet's Type. xpub wallets derive public keys on the external chain
+// only. Collection wallets, which cannot discover new keys, return
+// ErrWalletCannotGenerateAddresses.
+func (w *Wallet) GenerateAddresses(num uint64) ([]cipher.Address, error) {
+	if num == 0 {
+		return nil, nil
+	}
+
+	switch w.Type() {
+	case WalletTypeDeterministic:
+		return w.generateDeterministicAddresses(num)
+	case WalletTypeBIP44:
+		return w.generateBIP44Addresses(num, false)
+	case WalletTypeXPub:
+		return w.generateXPubAddresses(num)
+	case WalletTypeCollection:
+		return nil, ErrWalletCannotGenerateAddresses
+	default:
+		return nil, ErrWalletTypeNotSupported
+	}
+}
+
+func (w *Wallet) generateDeterministicAddresses(num uint64) ([]cipher.Address, error) {
+	seckeys := cipher.GenerateDeterministicKeyPairsSeed([]byte(w.lastSeed()), int(num))
+	addrs := make([]cipher.Address, len(seckeys))
+	for i, sk := range seckeys {
+		pk := cipher.PubKeyFromSecKey(sk)
+		addrs[i] = cipher.AddressFromPubKey(pk)
+		w.Entries = append(w.Entries, Entry{
+			Address: addrs[i],
+			Public:  pk,
+			Secret:  sk,
+		})
+	}
+	sum := cipher.SumSHA256([]byte(w.lastSeed()))
+	w.setLastSeed(string(sum[:]))
+	return addrs, nil
+}
+
+// ScanAddresses scans ahead scanN addresses, looking for the highest address
+// index with a nonzero balance, and keeps the entries up to that index.
+// Candidates come from GenerateAddresses, so xpub wallets scan their public
+// external chain only, and collection wallets return
+// ErrWalletCannotGenerateAddresses.
+func (w *Wallet) ScanAddresses(scanN uint64, bg BalanceGetter) error {
+	if scanN == 0 {
+		return nil
+	}
+
+	if w.Type() == WalletTypeCollection {
+		return ErrWalletCannotGenerateAddresses
+	}
+
+	// xpub wallets can scan their own (public) external chain, but have no
+	// private key to derive a hidden change chain from, so GenerateAddresses
+	// below only ever advances the external chain for them.
+	nExistingAddrs := uint64(len(w.Entries))
+
+	addrs, err := w.GenerateAddresses(scanN)
+	if err != nil {
+		return err
+	}
+
+	balances, err := bg.GetBalanceOfAddrs(addrs)
+	if err != nil {
+		return err
+	}
+
+	// find the last address with a nonzero balance
+	var keepNum uint64
+	for i, b := range balances {
+		if b.Confirmed.Coins > 0 || b.Predicted.Coins > 0 {
+			keepNum = uint64(i) + 1
+		}
+	}
+
+	w.Entries = w.Entries[:nExistingAddrs+keepNum]
+
+	return nil
+}
+
+// Copy returns a copy of the wallet safe to return to callers outside the
+// service's lock
+func (w *Wallet) Copy() Wallet {
+	return *w.clone()
+}
+
+func (w *Wallet) clone() *Wallet {
+	nw := &Wallet{
+		Meta:    make(map[string]string, len(w.Meta)),
+		Entries: make([]Entry, len(w.Entries)),
+	}
+	for k, v := range w.Meta {
+		nw.Meta[k] = v
+	}
+	copy(nw.Entries, w.Entries)
+	return nw
+}
+
+// Save writes the wallet to walletDir
+func (w *Wallet) Save(walletDir string) error {
+	return Save(walletDir, w)
+}
+
+// decryptWallet returns a decrypted copy of w, leaving w itself untouched.
+// It is shared by Service.Unlock, which caches the result, and
+// Service.ExportWallet, which doesn't.
+func decryptWallet(w *Wallet, password string) (*Wallet, error) {
+	uw := w.clone()
+
+	if uw.seed() != "" {
+		seed, err := Decrypt([]byte(uw.seed()), []byte(password))
+		if err != nil {
+			return nil, err
+		}
+		uw.setSeed(string(seed))
+
+		lastSeed, err := Decrypt([]byte(uw.lastSeed()), []byte(password))
+		if err != nil {
+			return nil, err
+		}
+		uw.setLastSeed(string(lastSeed))
+	}
+
+	for i := range uw.Entries {
+		if len(uw.Entries[i].EncryptedSeckey) == 0 {
+			continue
+		}
+
+		sk, err := Decrypt(uw.Entries[i].EncryptedSeckey, []byte(password))
+		if err != nil {
+			return nil, err
+		}
+		copy(uw.Entries[i].Secret[:], sk)
+	}
+
+	return uw, nil
+}
+
+// CreateAndSignTransaction creates and signs a transaction spending coins to
+// dest. It returns ErrNoPrivateKey for an xpub wallet, or when any entry's
+// Secret is zeroed, as in an encrypted wallet's own entries; the
+// WalletLockedError for a locked wallet comes from
+// Service.CreateAndSignTransaction, not from here.
+func (w *Wallet) CreateAndSignTransaction(vld Validator, unspent blockdb.UnspentGetter,
+	headTime, coins uint64, dest cipher.Address) (*coin.Transaction, error) {
+	if !w.HasSecrets() {
+		return nil, ErrNoPrivateKey
+	}
+
+	auxs, err := unspent.GetUnspentsOfAddrs(w.GetAddresses())
+	if err != nil {
+		return nil, err
+	}
+
+	txIn, totalCoins, totalHours, err := chooseSpends(auxs, coins, headTime)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]cipher.SecKey, 0, len(txIn))
+	secretOf := make(map[cipher.Address]cipher.SecKey, len(w.Entries))
+	for _, e := range w.Entries {
+		if e.Secret == (cipher.SecKey{}) {
+			return nil, ErrNoPrivateKey
+		}
+		secretOf[e.Address] = e.Secret
+	}
+
+	tx := coin.Transaction{}
+	for _, in := range txIn {
+		tx.PushInput(in.Hash)
+		keys = append(keys, secretOf[in.Body.Address])
+	}
+
+	changeHours := totalHours / 2
+	if totalCoins > coins {
+		changeEntry := w.Entries[0]
+		tx.PushOutput(changeEntry.Address, totalCoins-coins, changeHours)
+	}
+	tx.PushOutput(dest, coins, totalHours-changeHours)
+
+	tx.SignInputs(keys)
+	tx.UpdateHeader()
+
+	if has, err := vld.HasUnconfirmedSpendTx(w.GetAddresses()); err != nil {
+		return nil, err
+	} else if has {
+		return nil, errors.New("wallet has unconfirmed spending transaction")
+	}
+
+	return &tx, nil
+}
+
+// chooseSpends greedily selects unspent outputs until coins can be covered,
+// returning the selected outputs along with their total coins and hours
+func chooseSpends(auxs coin.UxArray, coins, headTime uint64) (coin.UxArray, uint64, uint64, error) {
+	var txIn coin.UxArray
+	var totalCoins, totalHours uint64
+	for _, ux := range auxs {
+		txIn = append(txIn, ux)
+		totalCoins += ux.Body.Coins
+		totalHours += ux.CoinHours(headTime)
+		if totalCoins >= coins {
+			return txIn, totalCoins, totalHours, nil
+		}
+	}
+
+	return nil, 0, 0, errors.New("not enough balance")
+}
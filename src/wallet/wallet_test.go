@@ -0,0 +1,53 @@
+package wallet
+
+import "testing"
+
+func TestNewWalletRequiresSeed(t *testing.T) {
+	for _, typ := range []string{WalletTypeDeterministic, WalletTypeBIP44} {
+		if _, err := NewWallet("test.wlt", Options{Type: typ}); err != ErrMissingSeed {
+			t.Errorf("NewWallet(%s) with no seed = %v, want ErrMissingSeed", typ, err)
+		}
+	}
+}
+
+func TestNewWalletRequiresXPub(t *testing.T) {
+	if _, err := NewWallet("test.wlt", Options{Type: WalletTypeXPub}); err != ErrMissingXPub {
+		t.Errorf("NewWallet(xpub) with no xpub = %v, want ErrMissingXPub", err)
+	}
+}
+
+func TestNewWalletUnknownType(t *testing.T) {
+	if _, err := NewWallet("test.wlt", Options{Type: "bogus"}); err != ErrWalletTypeNotSupported {
+		t.Errorf("NewWallet with unknown type = %v, want ErrWalletTypeNotSupported", err)
+	}
+}
+
+func TestCollectionWalletCannotGenerateAddresses(t *testing.T) {
+	w, err := NewWallet("test.wlt", Options{Type: WalletTypeCollection})
+	if err != nil {
+		t.Fatalf("NewWallet(collection) error: %v", err)
+	}
+	if _, err := w.GenerateAddresses(1); err != ErrWalletCannotGenerateAddresses {
+		t.Errorf("GenerateAddresses on collection wallet = %v, want ErrWalletCannotGenerateAddresses", err)
+	}
+}
+
+func TestNextChildNumber(t *testing.T) {
+	w := &Wallet{Meta: map[string]string{"type": WalletTypeBIP44}}
+	if next := w.nextChildNumber(false); next != 0 {
+		t.Fatalf("nextChildNumber on empty wallet = %d, want 0", next)
+	}
+
+	w.Entries = []Entry{
+		{ChildNumber: 0, Change: false},
+		{ChildNumber: 1, Change: false},
+		{ChildNumber: 0, Change: true},
+	}
+
+	if next := w.nextChildNumber(false); next != 2 {
+		t.Errorf("nextChildNumber(external) = %d, want 2", next)
+	}
+	if next := w.nextChildNumber(true); next != 1 {
+		t.Errorf("nextChildNumber(change) = %d, want 1", next)
+	}
+}
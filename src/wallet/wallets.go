@@ -0,0 +1,83 @@
+package wallet
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+)
+
+// Wallets maps wallet filename to wallet
+type Wallets map[string]*Wallet
+
+// Add adds a wallet, returning an error if a wallet with the same id already exists
+func (wlts Wallets) Add(w Wallet) error {
+	if _, ok := wlts[w.GetID()]; ok {
+		return ErrWalletDuplicate{w.GetID()}
+	}
+	wlts[w.GetID()] = &w
+	return nil
+}
+
+// Remove removes the wallet of given id
+func (wlts Wallets) Remove(wltID string) {
+	delete(wlts, wltID)
+}
+
+// Get returns the wallet of given id
+func (wlts Wallets) Get(wltID string) (*Wallet, bool) {
+	w, ok := wlts[wltID]
+	return w, ok
+}
+
+// set sets or replaces a wallet, keyed by its own id
+func (wlts Wallets) set(w *Wallet) {
+	wlts[w.GetID()] = w
+}
+
+// update looks up the wallet of given id and applies f to it, returning
+// ErrWalletNotExist if it does not exist
+func (wlts Wallets) update(wltID string, f func(w *Wallet) *Wallet) error {
+	w, ok := wlts[wltID]
+	if !ok {
+		return ErrWalletNotExist{wltID}
+	}
+	wlts[wltID] = f(w)
+	return nil
+}
+
+// NewWalletFilename returns a new timestamped wallet filename
+func NewWalletFilename() string {
+	return fmt.Sprintf("%d_%s.wlt", time.Now().Unix(), randSuffix())
+}
+
+// LoadWallets loads all wallet files in walletDir
+func LoadWallets(walletDir string) (Wallets, error) {
+	entries, err := ioutil.ReadDir(walletDir)
+	if err != nil {
+		return nil, err
+	}
+
+	wlts := make(Wallets)
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".wlt" {
+			continue
+		}
+
+		w, err := loadWalletFile(filepath.Join(walletDir, e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load wallet %s: %v", e.Name(), err)
+		}
+
+		if err := wlts.Add(*w); err != nil {
+			return nil, err
+		}
+	}
+
+	return wlts, nil
+}
+
+// Save writes w to walletDir
+func Save(walletDir string, w *Wallet) error {
+	return saveWalletFile(filepath.Join(walletDir, w.Filename()), w)
+}
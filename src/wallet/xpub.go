@@ -0,0 +1,72 @@
+package wallet
+
+import (
+	"fmt"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/go-bip32"
+)
+
+// newXPubWallet creates a watch-only wallet from a serialized extended
+// public key. It never holds a private key, so signing and any scan that
+// would need to derive the hidden change chain are rejected.
+func newXPubWallet(wltName string, options Options) (*Wallet, error) {
+	if options.XPub == "" {
+		return nil, ErrMissingXPub
+	}
+
+	if _, err := bip32.DeserializeExtendedPublicKey(options.XPub); err != nil {
+		return nil, fmt.Errorf("invalid xpub: %v", err)
+	}
+
+	w := &Wallet{
+		Meta: map[string]string{
+			"filename": wltName,
+			"label":    options.Label,
+			"xpub":     options.XPub,
+			"type":     WalletTypeXPub,
+			"version":  Version,
+			"coin":     "skycoin",
+		},
+	}
+
+	return w, nil
+}
+
+// generateXPubAddresses derives the next num addresses on the xpub's
+// external chain. There is no seed, so only public keys are produced.
+func (w *Wallet) generateXPubAddresses(num uint64) ([]cipher.Address, error) {
+	key, err := bip32.DeserializeExtendedPublicKey(w.Meta["xpub"])
+	if err != nil {
+		return nil, err
+	}
+
+	externalChain, err := key.NewChildKey(bip44ExternalChain)
+	if err != nil {
+		return nil, err
+	}
+
+	next := w.nextChildNumber(false)
+
+	addrs := make([]cipher.Address, 0, num)
+	for i := uint64(0); i < num; i++ {
+		childKey, err := externalChain.NewChildKey(next)
+		if err != nil {
+			return nil, err
+		}
+
+		pk := cipher.NewPubKey(childKey.Key)
+		addr := cipher.AddressFromPubKey(pk)
+
+		w.Entries = append(w.Entries, Entry{
+			Address:     addr,
+			Public:      pk,
+			ChildNumber: next,
+		})
+		addrs = append(addrs, addr)
+
+		next++
+	}
+
+	return addrs, nil
+}
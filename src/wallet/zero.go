@@ -0,0 +1,16 @@
+package wallet
+
+// zero overwrites b's bytes with zeroes, mirroring the zero-on-lock
+// pattern used by btcwallet's internal/zero package, so a decrypted
+// secret key doesn't linger in memory after Service.Lock.
+//
+// This only scrubs fixed-size byte arrays such as cipher.SecKey. Go
+// strings are immutable and get copied by the runtime on assignment and
+// conversion, so there is no way to reach every copy of a decrypted seed
+// stored as a string; Service.lock can only drop its reference to it, not
+// guarantee the original bytes are wiped.
+func zero(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}